@@ -0,0 +1,43 @@
+package uow
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SQLAdapter expoe um *sql.DB como TxBeginner, para compor um Uow sobre
+// database/sql puro
+func SQLAdapter(db *sql.DB) TxBeginner {
+	return &sqlBeginner{db: db}
+}
+
+type sqlBeginner struct {
+	db *sql.DB
+}
+
+func (b *sqlBeginner) BeginTx(ctx context.Context, opts *TxOptions) (Tx, error) {
+	var sqlOpts *sql.TxOptions
+	if opts != nil {
+		sqlOpts = &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}
+	}
+
+	tx, err := b.db.BeginTx(ctx, sqlOpts)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{tx: tx}, nil
+}
+
+//sqlTx adapta *sql.Tx para a interface Tx
+type sqlTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqlTx) ExecContext(ctx context.Context, query string, args ...any) error {
+	_, err := t.tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (t *sqlTx) Commit() error   { return t.tx.Commit() }
+func (t *sqlTx) Rollback() error { return t.tx.Rollback() }
+func (t *sqlTx) Raw() any        { return t.tx }
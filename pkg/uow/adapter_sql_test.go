@@ -0,0 +1,108 @@
+package uow
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+//fakeSQLDriver e um database/sql driver minimo, o bastante para exercitar
+//SQLAdapter/sqlBeginner/sqlTx sem precisar de um banco de verdade
+type fakeSQLDriver struct{}
+
+func (fakeSQLDriver) Open(name string) (driver.Conn, error) { return &fakeSQLConn{}, nil }
+
+type fakeSQLConn struct {
+	gotTxOpts []driver.TxOptions
+}
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) { return &fakeSQLStmt{}, nil }
+func (c *fakeSQLConn) Close() error                              { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error)                 { return &fakeSQLTx{}, nil }
+
+func (c *fakeSQLConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.gotTxOpts = append(c.gotTxOpts, opts)
+	return &fakeSQLTx{}, nil
+}
+
+func (c *fakeSQLConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+type fakeSQLStmt struct{}
+
+func (s *fakeSQLStmt) Close() error                                    { return nil }
+func (s *fakeSQLStmt) NumInput() int                                   { return -1 }
+func (s *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) { return driver.RowsAffected(0), nil }
+func (s *fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error)  { return nil, sql.ErrNoRows }
+
+type fakeSQLTx struct{}
+
+func (fakeSQLTx) Commit() error   { return nil }
+func (fakeSQLTx) Rollback() error { return nil }
+
+func openFakeSQLDB(t *testing.T) *sql.DB {
+	t.Helper()
+	driverName := "fake-uow-" + t.Name()
+	sql.Register(driverName, fakeSQLDriver{})
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLAdapterBeginTxWrapsOptsAndTx(t *testing.T) {
+	db := openFakeSQLDB(t)
+	beginner := SQLAdapter(db)
+
+	opts := &TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}
+	tx, err := beginner.BeginTx(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+
+	if _, ok := tx.Raw().(*sql.Tx); !ok {
+		t.Fatalf("Raw() = %T, want *sql.Tx", tx.Raw())
+	}
+	if err := tx.ExecContext(context.Background(), "SAVEPOINT sp_1"); err != nil {
+		t.Fatalf("ExecContext: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	//apos o Commit a conexao volta ao pool; pega-la de novo mostra quais
+	//opts o BeginTx do driver recebeu de sqlBeginner
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("db.Conn: %v", err)
+	}
+	defer conn.Close()
+	var got []driver.TxOptions
+	if err := conn.Raw(func(dc any) error {
+		got = dc.(*fakeSQLConn).gotTxOpts
+		return nil
+	}); err != nil {
+		t.Fatalf("conn.Raw: %v", err)
+	}
+	want := driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSerializable), ReadOnly: true}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("driver BeginTx opts = %v, want [%v]", got, want)
+	}
+}
+
+func TestSQLAdapterBeginTxWithNilOpts(t *testing.T) {
+	db := openFakeSQLDB(t)
+	beginner := SQLAdapter(db)
+
+	tx, err := beginner.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package uow
+
+import "context"
+
+//chave nao exportada para evitar colisao no context
+type ctxKey struct{}
+
+//txState guarda o estado de uma unica execucao de Do: a transacao, a
+//profundidade de savepoints aninhados e os hooks registrados por essa
+//invocacao. Fica preso ao context (nao a campos do *Uow) para que um
+//unico *Uow registrado possa ser compartilhado por goroutines
+//concorrentes sem que uma pise na transacao/hooks da outra.
+type txState struct {
+	tx         Tx
+	depth      int
+	savepoints []string
+
+	beforeCommitHooks  []beforeCommitHook
+	afterCommitHooks   []afterCommitHook
+	afterRollbackHooks []afterRollbackHook
+}
+
+//WithTx devolve um novo context.Context carregando a transacao informada,
+//permitindo que um unico *Uow registrado seja compartilhado entre goroutines
+//e cada chamada fique presa a sua propria transacao
+func WithTx(ctx context.Context, tx Tx) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &txState{tx: tx})
+}
+
+//From devolve a transacao armazenada no context, se existir
+func From(ctx context.Context) (Tx, bool) {
+	state, ok := stateFrom(ctx)
+	if !ok {
+		return nil, false
+	}
+	return state.tx, true
+}
+
+//HasTx indica se o context ja carrega uma transacao
+func HasTx(ctx context.Context) bool {
+	_, ok := From(ctx)
+	return ok
+}
+
+//stateFrom devolve o *txState preso ao context, se existir
+func stateFrom(ctx context.Context) (*txState, bool) {
+	state, ok := ctx.Value(ctxKey{}).(*txState)
+	return state, ok
+}
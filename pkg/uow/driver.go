@@ -0,0 +1,32 @@
+package uow
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Tx abstrai uma transacao em andamento, qualquer que seja o driver por
+// baixo (database/sql, pgx, gorm...), para que o Uow nao dependa de
+// *sql.Tx diretamente
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...any) error
+	Commit() error
+	Rollback() error
+	//Raw devolve o valor concreto do driver (*sql.Tx, pgx.Tx, *gorm.DB...)
+	//para que cada RepositoryFactory faca o unwrap para o tipo que conhece
+	Raw() any
+}
+
+// TxBeginner abstrai quem sabe abrir uma transacao: *sql.DB, pgxpool.Pool,
+// *gorm.DB etc
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *TxOptions) (Tx, error)
+}
+
+// TxOptions espelha sql.TxOptions para nao amarrar a assinatura de
+// BeginTx ao pacote database/sql; cada adapter traduz para o tipo do
+// seu proprio driver
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+}
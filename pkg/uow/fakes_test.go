@@ -0,0 +1,73 @@
+package uow
+
+import "context"
+
+//fakeTx e um Tx em memoria usado pelos testes para observar os
+//comandos emitidos pelo Uow (SAVEPOINT/RELEASE/ROLLBACK TO, Commit,
+//Rollback) sem precisar de um banco de verdade
+type fakeTx struct {
+	execs       []string
+	execErr     error
+	commitErr   error
+	rollbackErr error
+	committed   bool
+	rolledBack  bool
+}
+
+func (t *fakeTx) ExecContext(ctx context.Context, query string, args ...any) error {
+	t.execs = append(t.execs, query)
+	return t.execErr
+}
+
+func (t *fakeTx) Commit() error {
+	t.committed = true
+	return t.commitErr
+}
+
+func (t *fakeTx) Rollback() error {
+	t.rolledBack = true
+	return t.rollbackErr
+}
+
+func (t *fakeTx) Raw() any { return t }
+
+//fakeBeginner devolve sempre o mesmo *fakeTx, suficiente para testar uma
+//unica execucao de Do (com ou sem chamadas aninhadas). Guarda os opts de
+//cada BeginTx recebido para que os testes verifiquem que DefaultTxOptions/
+//DoTx realmente chegam ao TxBeginner
+type fakeBeginner struct {
+	tx       *fakeTx
+	beginErr error
+	gotOpts  []*TxOptions
+}
+
+func (b *fakeBeginner) BeginTx(ctx context.Context, opts *TxOptions) (Tx, error) {
+	b.gotOpts = append(b.gotOpts, opts)
+	if b.beginErr != nil {
+		return nil, b.beginErr
+	}
+	return b.tx, nil
+}
+
+//sequentialBeginner devolve um *fakeTx diferente a cada BeginTx, na ordem
+//dada, para simular cada tentativa de DoWithRetry abrindo sua propria
+//transacao. Tambem guarda os opts recebidos em cada chamada
+type sequentialBeginner struct {
+	txs     []*fakeTx
+	i       int
+	gotOpts []*TxOptions
+}
+
+func (b *sequentialBeginner) BeginTx(ctx context.Context, opts *TxOptions) (Tx, error) {
+	b.gotOpts = append(b.gotOpts, opts)
+	tx := b.txs[b.i]
+	b.i++
+	return tx, nil
+}
+
+func newTestUow(db TxBeginner) *Uow {
+	return &Uow{
+		DB:           db,
+		Repositories: make(map[string]RepositoryFactory),
+	}
+}
@@ -0,0 +1,32 @@
+package uow
+
+import (
+	"context"
+	"fmt"
+)
+
+//GetRepositoryAs resolve o repositorio registrado sob name e o converte
+//para T, devolvendo um erro tipado em vez de deixar o caller fazer o
+//type assertion e o panic check manualmente
+func GetRepositoryAs[T any](ctx context.Context, uow *Uow, name string) (T, error) {
+	var zero T
+
+	repository, err := uow.GetRepository(ctx, name)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := repository.(T)
+	if !ok {
+		return zero, fmt.Errorf("repository %q registered as %T, not %T", name, repository, zero)
+	}
+	return typed, nil
+}
+
+//MustRegister registra factory preservando o tipo concreto T que ela
+//devolve, sem o caller precisar converter para RepositoryFactory na mao
+func MustRegister[T any](uow *Uow, name string, factory func(tx Tx) T) {
+	uow.Register(name, func(tx Tx) any {
+		return factory(tx)
+	})
+}
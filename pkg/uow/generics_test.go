@@ -0,0 +1,55 @@
+package uow
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeOrderRepo struct{ tx Tx }
+
+func TestGetRepositoryAsReturnsTypedRepository(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+	MustRegister(u, "orders", func(tx Tx) *fakeOrderRepo {
+		return &fakeOrderRepo{tx: tx}
+	})
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		repo, err := GetRepositoryAs[*fakeOrderRepo](ctx, u, "orders")
+		if err != nil {
+			return err
+		}
+		if repo.tx != tx {
+			t.Fatal("expected repo to be bound to the context's tx")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetRepositoryAsTypeMismatchReturnsError(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+	MustRegister(u, "orders", func(tx Tx) *fakeOrderRepo {
+		return &fakeOrderRepo{tx: tx}
+	})
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		_, err := GetRepositoryAs[string](ctx, u, "orders")
+		return err
+	})
+	if err == nil {
+		t.Fatal("expected a typed error when the registered repository doesn't match T")
+	}
+}
+
+func TestGetRepositoryAsPropagatesGetRepositoryError(t *testing.T) {
+	u := newTestUow(&fakeBeginner{tx: &fakeTx{}})
+
+	_, err := GetRepositoryAs[*fakeOrderRepo](context.Background(), u, "orders")
+	if err == nil {
+		t.Fatal("expected an error when ctx carries no transaction")
+	}
+}
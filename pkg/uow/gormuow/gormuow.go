@@ -0,0 +1,46 @@
+// Package gormuow adapta *gorm.DB para a interface uow.TxBeginner,
+// reaproveitando o Begin/Commit/Rollback do GORM por baixo do mesmo Uow
+package gormuow
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ruhancs/uow-model/pkg/uow"
+	"gorm.io/gorm"
+)
+
+//NewAdapter expoe um *gorm.DB como uow.TxBeginner
+func NewAdapter(db *gorm.DB) uow.TxBeginner {
+	return &adapter{db: db}
+}
+
+type adapter struct {
+	db *gorm.DB
+}
+
+func (a *adapter) BeginTx(ctx context.Context, opts *uow.TxOptions) (uow.Tx, error) {
+	var sqlOpts *sql.TxOptions
+	if opts != nil {
+		sqlOpts = &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}
+	}
+
+	tx := a.db.WithContext(ctx).Begin(sqlOpts)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &gormTx{db: tx}, nil
+}
+
+//gormTx adapta *gorm.DB (ja em transacao) para a interface uow.Tx
+type gormTx struct {
+	db *gorm.DB
+}
+
+func (t *gormTx) ExecContext(ctx context.Context, query string, args ...any) error {
+	return t.db.WithContext(ctx).Exec(query, args...).Error
+}
+
+func (t *gormTx) Commit() error   { return t.db.Commit().Error }
+func (t *gormTx) Rollback() error { return t.db.Rollback().Error }
+func (t *gormTx) Raw() any        { return t.db }
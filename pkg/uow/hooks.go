@@ -0,0 +1,69 @@
+package uow
+
+import (
+	"context"
+	"errors"
+)
+
+// hooks disparados ao redor do commit/rollback da transacao corrente.
+// Sao escopados a uma unica chamada de Do: servicos os registram de
+// dentro de fn (via o ctx recebido) para adiar publicacao de outbox,
+// invalidacao de cache e disparo de eventos de dominio ate a transacao
+// realmente comitar. Ficam presos ao *txState do context, nao ao *Uow,
+// para nao vazar entre chamadas concorrentes de um mesmo *Uow registrado.
+type beforeCommitHook func(ctx context.Context) error
+type afterCommitHook func(ctx context.Context)
+type afterRollbackHook func(ctx context.Context, cause error)
+
+//RegisterBeforeCommit roda fn antes do commit da transacao presa a ctx;
+//um erro aborta o commit e dispara o rollback no lugar
+func (uow *Uow) RegisterBeforeCommit(ctx context.Context, fn func(ctx context.Context) error) error {
+	state, ok := stateFrom(ctx)
+	if !ok {
+		return errors.New("no transaction in context to register a hook for")
+	}
+	state.beforeCommitHooks = append(state.beforeCommitHooks, fn)
+	return nil
+}
+
+//RegisterAfterCommit roda fn somente depois que a transacao presa a ctx
+//comitou com sucesso
+func (uow *Uow) RegisterAfterCommit(ctx context.Context, fn func(ctx context.Context)) error {
+	state, ok := stateFrom(ctx)
+	if !ok {
+		return errors.New("no transaction in context to register a hook for")
+	}
+	state.afterCommitHooks = append(state.afterCommitHooks, fn)
+	return nil
+}
+
+//RegisterAfterRollback roda fn depois que a transacao presa a ctx sofreu rollback
+func (uow *Uow) RegisterAfterRollback(ctx context.Context, fn func(ctx context.Context, cause error)) error {
+	state, ok := stateFrom(ctx)
+	if !ok {
+		return errors.New("no transaction in context to register a hook for")
+	}
+	state.afterRollbackHooks = append(state.afterRollbackHooks, fn)
+	return nil
+}
+
+func runBeforeCommitHooks(ctx context.Context, state *txState) error {
+	for _, hook := range state.beforeCommitHooks {
+		if err := hook(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runAfterCommitHooks(ctx context.Context, state *txState) {
+	for _, hook := range state.afterCommitHooks {
+		hook(ctx)
+	}
+}
+
+func runAfterRollbackHooks(ctx context.Context, state *txState, cause error) {
+	for _, hook := range state.afterRollbackHooks {
+		hook(ctx, cause)
+	}
+}
@@ -0,0 +1,168 @@
+package uow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestHooksRunInOrderOnCommit(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	var order []string
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		if err := u.RegisterBeforeCommit(ctx, func(ctx context.Context) error {
+			order = append(order, "before-commit")
+			return nil
+		}); err != nil {
+			t.Fatalf("RegisterBeforeCommit: %v", err)
+		}
+		if err := u.RegisterAfterCommit(ctx, func(ctx context.Context) {
+			order = append(order, "after-commit")
+		}); err != nil {
+			t.Fatalf("RegisterAfterCommit: %v", err)
+		}
+		if err := u.RegisterAfterRollback(ctx, func(ctx context.Context, cause error) {
+			order = append(order, "after-rollback")
+		}); err != nil {
+			t.Fatalf("RegisterAfterRollback: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tx.committed {
+		t.Fatal("expected transaction to commit")
+	}
+
+	want := []string{"before-commit", "after-commit"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("hook order = %v, want %v", order, want)
+	}
+}
+
+func TestBeforeCommitHookErrorAbortsCommitAndRollsBack(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	hookErr := errors.New("outbox publish failed")
+	var order []string
+	var gotCause error
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		_ = u.RegisterBeforeCommit(ctx, func(ctx context.Context) error {
+			order = append(order, "before-commit")
+			return hookErr
+		})
+		_ = u.RegisterAfterCommit(ctx, func(ctx context.Context) {
+			order = append(order, "after-commit")
+		})
+		_ = u.RegisterAfterRollback(ctx, func(ctx context.Context, cause error) {
+			order = append(order, "after-rollback")
+			gotCause = cause
+		})
+		return nil
+	})
+	if !errors.Is(err, hookErr) {
+		t.Fatalf("err = %v, want %v", err, hookErr)
+	}
+	if tx.committed {
+		t.Fatal("commit should have been aborted by the before-commit hook")
+	}
+	if !tx.rolledBack {
+		t.Fatal("expected transaction to roll back")
+	}
+	if !errors.Is(gotCause, hookErr) {
+		t.Fatalf("after-rollback cause = %v, want %v", gotCause, hookErr)
+	}
+
+	want := []string{"before-commit", "after-rollback"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("hook order = %v, want %v", order, want)
+	}
+}
+
+func TestAfterRollbackHookRunsOnFnError(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	fnErr := errors.New("business rule violated")
+	var gotCause error
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		_ = u.RegisterAfterRollback(ctx, func(ctx context.Context, cause error) {
+			gotCause = cause
+		})
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("err = %v, want %v", err, fnErr)
+	}
+	if !errors.Is(gotCause, fnErr) {
+		t.Fatalf("after-rollback cause = %v, want %v", gotCause, fnErr)
+	}
+}
+
+func TestNestedDoDiscardsCommitHooksOnSavepointRollback(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	innerErr := errors.New("inner failure")
+	var order []string
+	var gotCause error
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		_ = u.RegisterAfterCommit(ctx, func(ctx context.Context) {
+			order = append(order, "outer-after-commit")
+		})
+
+		innerErrGot := u.Do(ctx, func(ctx context.Context, u *Uow) error {
+			_ = u.RegisterBeforeCommit(ctx, func(ctx context.Context) error {
+				order = append(order, "inner-before-commit")
+				return nil
+			})
+			_ = u.RegisterAfterCommit(ctx, func(ctx context.Context) {
+				order = append(order, "inner-after-commit")
+			})
+			_ = u.RegisterAfterRollback(ctx, func(ctx context.Context, cause error) {
+				order = append(order, "inner-after-rollback")
+				gotCause = cause
+			})
+			return innerErr
+		})
+		if !errors.Is(innerErrGot, innerErr) {
+			t.Fatalf("inner Do error = %v, want %v", innerErrGot, innerErr)
+		}
+		//o bloco aninhado falhou e foi desfeito via savepoint, mas o caller
+		//decide seguir em frente e comitar a transacao externa mesmo assim
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error from outer Do: %v", err)
+	}
+	if !tx.committed {
+		t.Fatal("expected outer transaction to commit")
+	}
+	if !errors.Is(gotCause, innerErr) {
+		t.Fatalf("inner after-rollback cause = %v, want %v", gotCause, innerErr)
+	}
+
+	//os hooks de commit registrados dentro do bloco aninhado nao podem
+	//disparar no commit externo: o trabalho que eles acompanhavam foi
+	//desfeito pelo ROLLBACK TO SAVEPOINT. O after-rollback do nivel
+	//aninhado reage imediatamente ao rollback do savepoint.
+	want := []string{"inner-after-rollback", "outer-after-commit"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("hook order = %v, want %v", order, want)
+	}
+}
+
+func TestRegisterHookWithoutTransactionReturnsError(t *testing.T) {
+	u := newTestUow(&fakeBeginner{tx: &fakeTx{}})
+	if err := u.RegisterBeforeCommit(context.Background(), func(ctx context.Context) error { return nil }); err == nil {
+		t.Fatal("expected an error when registering a hook outside of Do")
+	}
+}
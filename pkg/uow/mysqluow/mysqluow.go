@@ -0,0 +1,21 @@
+// Package mysqluow extrai o numero de erro do driver go-sql-driver/mysql,
+// para uso com database/sql (via uow.SQLAdapter) sem o pacote core
+// depender do driver
+package mysqluow
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+//SQLStateOf extrai o numero de erro de um *mysql.MySQLError, para plugar em
+//uow.RetryPolicy.SQLStateOf
+func SQLStateOf(err error) (string, bool) {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return strconv.Itoa(int(myErr.Number)), true
+	}
+	return "", false
+}
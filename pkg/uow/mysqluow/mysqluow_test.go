@@ -0,0 +1,19 @@
+package mysqluow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestSQLStateOfMatchesMySQLError(t *testing.T) {
+	code, ok := SQLStateOf(&mysql.MySQLError{Number: 1213})
+	if !ok || code != "1213" {
+		t.Fatalf("SQLStateOf = (%q, %v), want (1213, true)", code, ok)
+	}
+
+	if _, ok := SQLStateOf(errors.New("generic error")); ok {
+		t.Fatal("expected no match for a non-mysql error")
+	}
+}
@@ -0,0 +1,79 @@
+// Package pgxuow adapta *pgxpool.Pool para a interface uow.TxBeginner,
+// permitindo que projetos sobre pgx usem o mesmo Uow sem reescrever a
+// camada de unit of work
+package pgxuow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ruhancs/uow-model/pkg/uow"
+)
+
+//NewAdapter expoe um *pgxpool.Pool como uow.TxBeginner
+func NewAdapter(pool *pgxpool.Pool) uow.TxBeginner {
+	return &adapter{pool: pool}
+}
+
+type adapter struct {
+	pool *pgxpool.Pool
+}
+
+func (a *adapter) BeginTx(ctx context.Context, opts *uow.TxOptions) (uow.Tx, error) {
+	pgxOpts := pgx.TxOptions{}
+	if opts != nil {
+		pgxOpts.IsoLevel = toPgxIsoLevel(opts.Isolation)
+		if opts.ReadOnly {
+			pgxOpts.AccessMode = pgx.ReadOnly
+		}
+	}
+
+	tx, err := a.pool.BeginTx(ctx, pgxOpts)
+	if err != nil {
+		return nil, err
+	}
+	return &pgxTx{tx: tx}, nil
+}
+
+//pgxTx adapta pgx.Tx para a interface uow.Tx
+type pgxTx struct {
+	tx pgx.Tx
+}
+
+func (t *pgxTx) ExecContext(ctx context.Context, query string, args ...any) error {
+	_, err := t.tx.Exec(ctx, query, args...)
+	return err
+}
+
+func (t *pgxTx) Commit() error   { return t.tx.Commit(context.Background()) }
+func (t *pgxTx) Rollback() error { return t.tx.Rollback(context.Background()) }
+func (t *pgxTx) Raw() any        { return t.tx }
+
+//SQLStateOf extrai o SQLSTATE de um erro do pgx, para plugar em
+//uow.RetryPolicy.SQLStateOf sem o pacote core depender do pgx
+func SQLStateOf(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code, true
+	}
+	return "", false
+}
+
+func toPgxIsoLevel(level sql.IsolationLevel) pgx.TxIsoLevel {
+	switch level {
+	case sql.LevelSerializable:
+		return pgx.Serializable
+	case sql.LevelRepeatableRead:
+		return pgx.RepeatableRead
+	case sql.LevelReadCommitted:
+		return pgx.ReadCommitted
+	case sql.LevelReadUncommitted:
+		return pgx.ReadUncommitted
+	default:
+		return ""
+	}
+}
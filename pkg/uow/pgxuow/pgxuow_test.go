@@ -0,0 +1,25 @@
+package pgxuow
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestSQLStateOfMatchesPgError(t *testing.T) {
+	code, ok := SQLStateOf(&pgconn.PgError{Code: "40001"})
+	if !ok || code != "40001" {
+		t.Fatalf("SQLStateOf = (%q, %v), want (40001, true)", code, ok)
+	}
+
+	code, ok = SQLStateOf(fmt.Errorf("query failed: %w", &pgconn.PgError{Code: "40P01"}))
+	if !ok || code != "40P01" {
+		t.Fatalf("wrapped SQLStateOf = (%q, %v), want (40P01, true)", code, ok)
+	}
+
+	if _, ok := SQLStateOf(errors.New("generic error")); ok {
+		t.Fatal("expected no match for a non-pgx error")
+	}
+}
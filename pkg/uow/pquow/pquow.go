@@ -0,0 +1,19 @@
+// Package pquow extrai o SQLSTATE de erros do driver lib/pq, para uso com
+// database/sql (via uow.SQLAdapter) sem o pacote core depender do driver
+package pquow
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+//SQLStateOf extrai o SQLSTATE de um erro do lib/pq, para plugar em
+//uow.RetryPolicy.SQLStateOf
+func SQLStateOf(err error) (string, bool) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code), true
+	}
+	return "", false
+}
@@ -0,0 +1,19 @@
+package pquow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestSQLStateOfMatchesPqError(t *testing.T) {
+	code, ok := SQLStateOf(&pq.Error{Code: "40001"})
+	if !ok || code != "40001" {
+		t.Fatalf("SQLStateOf = (%q, %v), want (40001, true)", code, ok)
+	}
+
+	if _, ok := SQLStateOf(errors.New("generic error")); ok {
+		t.Fatal("expected no match for a non-pq error")
+	}
+}
@@ -0,0 +1,57 @@
+package uow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetRepositoryWithoutTxInContextReturnsError(t *testing.T) {
+	u := newTestUow(&fakeBeginner{tx: &fakeTx{}})
+	u.Register("orders", func(tx Tx) any { return "orders-repo" })
+
+	_, err := u.GetRepository(context.Background(), "orders")
+	if err == nil {
+		t.Fatal("expected an error when calling GetRepository without a tx in ctx")
+	}
+}
+
+func TestGetRepositoryUsesTxFromContext(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	var gotTx Tx
+	u.Register("orders", func(tx Tx) any {
+		gotTx = tx
+		return "orders-repo"
+	})
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		repo, err := u.GetRepository(ctx, "orders")
+		if err != nil {
+			return err
+		}
+		if repo != "orders-repo" {
+			t.Fatalf("repo = %v, want orders-repo", repo)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotTx != tx {
+		t.Fatal("expected factory to receive the tx bound to ctx")
+	}
+}
+
+func TestGetRepositoryUnregisteredNameReturnsError(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		_, err := u.GetRepository(ctx, "missing")
+		return err
+	})
+	if err == nil {
+		t.Fatal("expected an error for an unregistered repository name")
+	}
+}
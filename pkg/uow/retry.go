@@ -0,0 +1,112 @@
+package uow
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+//codigos de erro retryable por padrao: serialization_failure e
+//deadlock_detected do Postgres, e deadlock (1213) e lock wait timeout
+//(1205) do MySQL
+var defaultRetryableCodes = map[string]bool{
+	"40001": true,
+	"40P01": true,
+	"1213":  true,
+	"1205":  true,
+}
+
+// RetryPolicy descreve como DoWithRetry deve reagir a falhas de
+// serializacao/deadlock: quantas vezes tentar, quanto esperar entre
+// tentativas e quais erros sao considerados retryable.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	Jitter      time.Duration
+
+	//codigos extras somados aos defaults (40001, 40P01, 1213, 1205)
+	RetryableCodes []string
+
+	//SQLStateOf extrai o codigo de erro (SQLSTATE do Postgres ou o numero
+	//de erro do MySQL) de um erro concreto do driver em uso. O pacote core
+	//nao importa nenhum driver, entao quem usa DoWithRetry injeta aqui o
+	//extrator do seu proprio adapter (ex: pgxuow.SQLStateOf)
+	SQLStateOf func(err error) (string, bool)
+
+	//Classifier, se definido, substitui inteiramente a checagem acima
+	Classifier func(err error) bool
+}
+
+//isRetryable decide se err justifica uma nova tentativa
+func (p RetryPolicy) isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if p.Classifier != nil {
+		return p.Classifier(err)
+	}
+	if p.SQLStateOf == nil {
+		return false
+	}
+
+	code, ok := p.SQLStateOf(err)
+	if !ok {
+		return false
+	}
+
+	if defaultRetryableCodes[code] {
+		return true
+	}
+	for _, extra := range p.RetryableCodes {
+		if code == extra {
+			return true
+		}
+	}
+	return false
+}
+
+//DoWithRetry executa fn dentro de uma nova transacao via Do, e tenta
+//novamente em uma transacao limpa quando o driver reporta um erro de
+//serializacao ou deadlock classificado por policy. Se ctx ja carrega uma
+//transacao, cada tentativa roda como savepoint aninhado dessa transacao
+//(via Do), e nao em uma transacao nova
+func (uow *Uow) DoWithRetry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context, uow *Uow) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		lastErr = uow.Do(ctx, fn)
+		if lastErr == nil {
+			return nil
+		}
+
+		if !policy.isRetryable(lastErr) {
+			return lastErr
+		}
+
+		//uow.Do ja fecha (rollback) a transacao da tentativa antes de
+		//devolver o erro, e seu estado fica preso ao context daquela
+		//chamada, entao nao sobra nada do uow (compartilhado) para limpar
+		//antes de tentar de novo
+
+		if attempt == maxAttempts-1 {
+			break
+		}
+
+		backoff := policy.BaseBackoff
+		if policy.Jitter > 0 {
+			backoff += time.Duration(rand.Int63n(int64(policy.Jitter)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+
+	return lastErr
+}
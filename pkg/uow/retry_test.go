@@ -0,0 +1,127 @@
+package uow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+//sqlStateOfPgOrMySQL e o extrator usado pelos testes deste arquivo, para
+//provar que RetryPolicy.SQLStateOf realmente plugado (ex: pgxuow.SQLStateOf,
+//mysqluow.SQLStateOf) faz DoWithRetry reconhecer o driver certo, sem o
+//pacote core depender de nenhum deles
+func sqlStateOfPgOrMySQL(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code, true
+	}
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return strconv.Itoa(int(myErr.Number)), true
+	}
+	return "", false
+}
+
+func TestIsRetryableMatchesTypedDriverErrors(t *testing.T) {
+	policy := RetryPolicy{SQLStateOf: sqlStateOfPgOrMySQL}
+
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"pg serialization_failure", &pgconn.PgError{Code: "40001"}, true},
+		{"pg deadlock_detected", &pgconn.PgError{Code: "40P01"}, true},
+		{"pg wrapped", fmt.Errorf("query failed: %w", &pgconn.PgError{Code: "40001"}), true},
+		{"pg unrelated code", &pgconn.PgError{Code: "23505"}, false},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, true},
+		{"mysql lock wait timeout", &mysql.MySQLError{Number: 1205}, true},
+		{"mysql unrelated code", &mysql.MySQLError{Number: 1062}, false},
+		//um erro generico cujo texto contem os digitos do codigo nao deve
+		//ser confundido com o codigo de verdade (o motivo do fix)
+		{"generic error with coincidental digits", errors.New("row 1213 not found"), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.isRetryable(tc.err); got != tc.want {
+				t.Fatalf("isRetryable(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableHonorsExtraCodesAndClassifier(t *testing.T) {
+	withExtra := RetryPolicy{SQLStateOf: sqlStateOfPgOrMySQL, RetryableCodes: []string{"55P03"}}
+	if !withExtra.isRetryable(&pgconn.PgError{Code: "55P03"}) {
+		t.Fatal("expected extra code to be retryable")
+	}
+
+	classifierCalls := 0
+	withClassifier := RetryPolicy{
+		Classifier: func(err error) bool {
+			classifierCalls++
+			return errors.Is(err, errors.New("never matches"))
+		},
+	}
+	if withClassifier.isRetryable(&pgconn.PgError{Code: "40001"}) {
+		t.Fatal("custom classifier should override default code matching")
+	}
+	if classifierCalls != 1 {
+		t.Fatalf("classifier called %d times, want 1", classifierCalls)
+	}
+}
+
+func TestIsRetryableWithoutSQLStateOfOrClassifierIsNeverRetryable(t *testing.T) {
+	policy := RetryPolicy{}
+	if policy.isRetryable(&pgconn.PgError{Code: "40001"}) {
+		t.Fatal("expected no retry without an SQLStateOf or Classifier wired in")
+	}
+}
+
+func TestDoWithRetryRetriesOnRetryableCommitError(t *testing.T) {
+	firstTx := &fakeTx{commitErr: &pgconn.PgError{Code: "40001"}}
+	secondTx := &fakeTx{}
+	u := newTestUow(&sequentialBeginner{txs: []*fakeTx{firstTx, secondTx}})
+
+	attempts := 0
+	err := u.DoWithRetry(context.Background(), RetryPolicy{MaxAttempts: 2, SQLStateOf: sqlStateOfPgOrMySQL}, func(ctx context.Context, u *Uow) error {
+		attempts++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("fn called %d times, want 2", attempts)
+	}
+	if !firstTx.rolledBack {
+		t.Fatal("expected first attempt's transaction to roll back")
+	}
+	if !secondTx.committed {
+		t.Fatal("expected second attempt's transaction to commit")
+	}
+}
+
+func TestDoWithRetryStopsOnNonRetryableError(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	businessErr := errors.New("validation failed")
+	attempts := 0
+	err := u.DoWithRetry(context.Background(), RetryPolicy{MaxAttempts: 3}, func(ctx context.Context, u *Uow) error {
+		attempts++
+		return businessErr
+	})
+	if !errors.Is(err, businessErr) {
+		t.Fatalf("err = %v, want %v", err, businessErr)
+	}
+	if attempts != 1 {
+		t.Fatalf("fn called %d times, want 1 (non-retryable error should not retry)", attempts)
+	}
+}
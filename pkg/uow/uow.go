@@ -8,106 +8,203 @@ import (
 )
 
 // factory de repositorios
-type RepositoryFactory func(tx *sql.Tx) any
+type RepositoryFactory func(tx Tx) any
 
 type UowInterface interface {
 	Register(name string, factory RepositoryFactory)
 	GetRepository(ctx context.Context, name string) (any, error)
-	Do(ctx context.Context, fn func(uow *Uow) error) error
-	CommitOrRollback() error
-	Rollback() error
+	Do(ctx context.Context, fn func(ctx context.Context, uow *Uow) error) error
+	DoTx(ctx context.Context, opts *TxOptions, fn func(ctx context.Context, uow *Uow) error) error
+	DoWithRetry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context, uow *Uow) error) error
+	CommitOrRollback(ctx context.Context) error
+	Rollback(ctx context.Context, cause error) error
 	UnRegister(name string)
 }
 
+//Uow carrega apenas a configuracao compartilhavel (driver e repositorios
+//registrados). O estado de uma transacao em andamento (Tx, profundidade
+//de savepoints, hooks) vive no *txState preso ao context de cada chamada
+//de Do, entao um unico *Uow registrado pode ser reaproveitado por
+//goroutines concorrentes sem que uma pise na transacao da outra.
 type Uow struct {
-	DB           *sql.DB
-	Tx           *sql.Tx
-	Repositories map[string]RepositoryFactory
+	DB               TxBeginner
+	Repositories     map[string]RepositoryFactory
+	DefaultTxOptions *TxOptions
 }
 
+//NewUow cria um Uow sobre um *sql.DB puro. Para pgx ou gorm, construa o
+//Uow com o TxBeginner do adapter correspondente (ex: pgxuow.NewAdapter)
 func NewUow(ctx context.Context, db *sql.DB) *Uow {
 	return &Uow{
-		DB: db,
+		DB: SQLAdapter(db),
 		Repositories: make(map[string]RepositoryFactory),
 	}
 }
 
 func (uow *Uow) Register(name string, factory RepositoryFactory) {
-	uow.Repositories[name] = factory 
+	uow.Repositories[name] = factory
 }
 
 func (uow *Uow) UnRegister(name string) {
-	delete(uow.Repositories,name) 
+	delete(uow.Repositories,name)
 }
 
-func (uow *Uow) Do(ctx context.Context, fn func(uow *Uow) error) error{
-	//evitar iniciar uma nova transacao com o tx ja rodando uma transacao
-	//verificar se o Tx do Uow esta ocupado
-	if uow.Tx != nil {
-		return fmt.Errorf("transaction already started")
+func (uow *Uow) Do(ctx context.Context, fn func(ctx context.Context, uow *Uow) error) error{
+	return uow.DoTx(ctx, uow.DefaultTxOptions, fn)
+}
+
+//DoTx e identico a Do mas permite escolher o nivel de isolamento e o modo
+//read-only da transacao, em vez de depender dos defaults do driver. Util
+//para relatorios somente leitura (ReadOnly: true) ou fluxos de movimentacao
+//de dinheiro que exigem sql.LevelSerializable/sql.LevelRepeatableRead.
+func (uow *Uow) DoTx(ctx context.Context, opts *TxOptions, fn func(ctx context.Context, uow *Uow) error) error{
+	//se o context ja carrega uma transacao, abre um savepoint aninhado
+	//ao inves de rejeitar a chamada, permitindo compor metodos que
+	//cada um quer seu proprio bloco atomico
+	if state, ok := stateFrom(ctx); ok {
+		return uow.doNested(ctx, state, fn)
 	}
 
 	//iniciar a transacao no db
-	tx,err := uow.DB.BeginTx(ctx,nil)
+	tx,err := uow.DB.BeginTx(ctx,opts)
 	if err != nil {
 		return err
 	}
-	uow.Tx = tx
-	
+
+	//o estado desta transacao fica preso ao context passado a fn, nunca em
+	//um campo do *Uow, para nao vazar para outra goroutine que reaproveite
+	//o mesmo *Uow registrado
+	ctx = context.WithValue(ctx, ctxKey{}, &txState{tx: tx})
+
 	//executar a transacao com todos repositorios
-	err = fn(uow)
+	err = fn(ctx, uow)
 	if err != nil {
 		//rollback em caso de algum erro na transacao
-		errRb := uow.Rollback()
+		errRb := uow.Rollback(ctx, err)
 		if errRb != nil {
 			return errors.New(fmt.Sprintf("error: %s, error rollback: %s", err.Error(),errRb.Error()))
 		}
 		return err
 	}
 
-	return uow.CommitOrRollback()
+	return uow.CommitOrRollback(ctx)
 }
 
-func (uow *Uow) Rollback() error {
-	//checar se existem transacoes rodando
-	if uow.Tx == nil {
-		return errors.New("no transactions to rollback")
+//doNested executa fn dentro de um SAVEPOINT da transacao ja aberta,
+//permitindo desfazer apenas o trecho aninhado sem encerrar a transacao externa
+func (uow *Uow) doNested(ctx context.Context, state *txState, fn func(ctx context.Context, uow *Uow) error) error {
+	state.depth++
+	sp := fmt.Sprintf("sp_%d", state.depth)
+	tx := state.tx
+
+	//marca ate onde os hooks ja existiam antes deste nivel: se ele sofrer
+	//rollback to savepoint, qualquer hook registrado durante fn pertence a
+	//trabalho desfeito e nao pode vazar para o commit do nivel externo
+	beforeCommitMark := len(state.beforeCommitHooks)
+	afterCommitMark := len(state.afterCommitHooks)
+	afterRollbackMark := len(state.afterRollbackHooks)
+
+	if err := tx.ExecContext(ctx, fmt.Sprintf("SAVEPOINT %s", sp)); err != nil {
+		state.depth--
+		return err
 	}
-	err := uow.Tx.Rollback()
+	state.savepoints = append(state.savepoints, sp)
+
+	err := fn(ctx, uow)
+
+	//remove o savepoint da pilha, ja fechando esse nivel de aninhamento
+	state.savepoints = state.savepoints[:len(state.savepoints)-1]
+	state.depth--
+
 	if err != nil {
+		//hooks de before/after-commit registrados neste nivel nunca vao
+		//comitar de verdade, entao sao descartados; os de after-rollback
+		//reagem agora, pois e exatamente o rollback que eles esperavam
+		newAfterRollbackHooks := append([]afterRollbackHook(nil), state.afterRollbackHooks[afterRollbackMark:]...)
+		state.beforeCommitHooks = state.beforeCommitHooks[:beforeCommitMark]
+		state.afterCommitHooks = state.afterCommitHooks[:afterCommitMark]
+		state.afterRollbackHooks = state.afterRollbackHooks[:afterRollbackMark]
+
+		if errRb := tx.ExecContext(ctx, fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", sp)); errRb != nil {
+			return errors.New(fmt.Sprintf("error: %s, error rollback to savepoint: %s", err.Error(), errRb.Error()))
+		}
+
+		for _, hook := range newAfterRollbackHooks {
+			hook(ctx, err)
+		}
+		return err
+	}
+
+	if err := tx.ExecContext(ctx, fmt.Sprintf("RELEASE SAVEPOINT %s", sp)); err != nil {
 		return err
 	}
-	uow.Tx = nil
 	return nil
 }
 
-func (uow *Uow) CommitOrRollback() error{
+func (uow *Uow) Rollback(ctx context.Context, cause error) error {
+	//checar se existe uma transacao presa a esse context
+	state, ok := stateFrom(ctx)
+	if !ok {
+		return errors.New("no transactions to rollback")
+	}
+
+	if err := state.tx.Rollback(); err != nil {
+		return err
+	}
+
+	runAfterRollbackHooks(ctx, state, cause)
+	return nil
+}
+
+func (uow *Uow) CommitOrRollback(ctx context.Context) error{
+	state, ok := stateFrom(ctx)
+	if !ok {
+		return errors.New("no transactions to commit")
+	}
+
+	//so comita a transacao de fato quando estamos no nivel mais externo,
+	//niveis aninhados sao fechados via savepoint em doNested
+	if state.depth > 0 {
+		return nil
+	}
+
+	//roda os hooks de before-commit; um erro aqui aborta o commit e
+	//dispara o rollback no lugar
+	if err := runBeforeCommitHooks(ctx, state); err != nil {
+		errRb := uow.Rollback(ctx, err)
+		if errRb != nil {
+			return errors.New(fmt.Sprintf("error: %s, error rollback: %s", err.Error(),errRb.Error()))
+		}
+		return err
+	}
+
 	//commit da transacao
-	err := uow.Tx.Commit()
-	if err != nil {
+	if err := state.tx.Commit(); err != nil {
 		//rollback em caso de algum erro na transacao
-		errRb := uow.Rollback()
+		errRb := uow.Rollback(ctx, err)
 		if errRb != nil {
 			return errors.New(fmt.Sprintf("error: %s, error rollback: %s", err.Error(),errRb.Error()))
 		}
 		return err
 	}
-	
-	uow.Tx = nil
+
+	runAfterCommitHooks(ctx, state)
 	return nil
 }
 
+//GetRepository resolve o repositorio registrado sob name usando a
+//transacao presa a ctx. ctx precisa ter sido produzido por Do/DoTx/WithTx:
+//abrir uma transacao nova aqui nunca teria quem desse Commit/Rollback
+//nela, entao e um erro chamar GetRepository fora de uma transacao em andamento
 func (uow *Uow) GetRepository(ctx context.Context, name string) (any, error) {
-	//se nao haver tx cria o tx e inseri no uow
-	if uow.Tx == nil {
-		tx,err := uow.DB.BeginTx(ctx,nil)
-		if err != nil {
-			return nil,err
-		}
-		uow.Tx = tx
+	tx, ok := From(ctx)
+	if !ok {
+		return nil, errors.New("uow: no transaction in context; call GetRepository with a context from Do/DoTx/WithTx")
 	}
 
-	//pegar o repositorio com a transacao iniciada
-	repository := uow.Repositories[name](uow.Tx)
-	return repository, nil
+	factory, ok := uow.Repositories[name]
+	if !ok {
+		return nil, fmt.Errorf("repository %q not registered", name)
+	}
+	return factory(tx), nil
 }
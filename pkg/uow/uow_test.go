@@ -0,0 +1,123 @@
+package uow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDoNestedReleasesSavepointOnSuccess(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		return u.Do(ctx, func(ctx context.Context, u *Uow) error {
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tx.committed {
+		t.Fatal("expected outer transaction to commit")
+	}
+	if tx.rolledBack {
+		t.Fatal("did not expect outer transaction to roll back")
+	}
+
+	want := []string{"SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1"}
+	if !reflect.DeepEqual(tx.execs, want) {
+		t.Fatalf("execs = %v, want %v", tx.execs, want)
+	}
+}
+
+func TestDoNestedRollsBackToSavepointOnInnerError(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	innerErr := errors.New("inner failure")
+	var gotInnerErr error
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		gotInnerErr = u.Do(ctx, func(ctx context.Context, u *Uow) error {
+			return innerErr
+		})
+		//o caller decide se a falha do bloco aninhado derruba a transacao
+		//externa tambem; aqui ele escolhe seguir em frente
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error from outer Do: %v", err)
+	}
+	if !errors.Is(gotInnerErr, innerErr) {
+		t.Fatalf("inner Do error = %v, want %v", gotInnerErr, innerErr)
+	}
+	if !tx.committed {
+		t.Fatal("expected outer transaction to still commit")
+	}
+
+	want := []string{"SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1"}
+	if !reflect.DeepEqual(tx.execs, want) {
+		t.Fatalf("execs = %v, want %v", tx.execs, want)
+	}
+}
+
+func TestDoTxPassesOptsToTxBeginner(t *testing.T) {
+	tx := &fakeTx{}
+	beginner := &fakeBeginner{tx: tx}
+	u := newTestUow(beginner)
+
+	opts := &TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}
+	err := u.DoTx(context.Background(), opts, func(ctx context.Context, u *Uow) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(beginner.gotOpts) != 1 || beginner.gotOpts[0] != opts {
+		t.Fatalf("BeginTx opts = %v, want %v", beginner.gotOpts, opts)
+	}
+}
+
+func TestDoUsesDefaultTxOptions(t *testing.T) {
+	tx := &fakeTx{}
+	beginner := &fakeBeginner{tx: tx}
+	u := newTestUow(beginner)
+	u.DefaultTxOptions = &TxOptions{Isolation: sql.LevelRepeatableRead}
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(beginner.gotOpts) != 1 || beginner.gotOpts[0] != u.DefaultTxOptions {
+		t.Fatalf("BeginTx opts = %v, want %v", beginner.gotOpts, u.DefaultTxOptions)
+	}
+}
+
+func TestDoNestedDepthTracksMultipleLevels(t *testing.T) {
+	tx := &fakeTx{}
+	u := newTestUow(&fakeBeginner{tx: tx})
+
+	err := u.Do(context.Background(), func(ctx context.Context, u *Uow) error {
+		return u.Do(ctx, func(ctx context.Context, u *Uow) error {
+			return u.Do(ctx, func(ctx context.Context, u *Uow) error {
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"SAVEPOINT sp_1", "SAVEPOINT sp_2",
+		"RELEASE SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_1",
+	}
+	if !reflect.DeepEqual(tx.execs, want) {
+		t.Fatalf("execs = %v, want %v", tx.execs, want)
+	}
+}